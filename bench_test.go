@@ -90,6 +90,63 @@ func makeSz(sz int, b *testing.B) {
 
 }
 
+// BenchmarkMakeParallel10000 benchmarks NewParallel with 10,000 nodes.
+func BenchmarkMakeParallel10000(b *testing.B) {
+	makeParallelSz(10000, b)
+}
+
+// BenchmarkMakeParallel100000 benchmarks NewParallel with 100,000 nodes.
+func BenchmarkMakeParallel100000(b *testing.B) {
+	makeParallelSz(100000, b)
+}
+
+// BenchmarkMakeParallel1000000 benchmarks NewParallel with 1,000,000 nodes.
+func BenchmarkMakeParallel1000000(b *testing.B) {
+	makeParallelSz(1000000, b)
+}
+
+// BenchmarkMake10000 benchmarks the sequential New with 10,000 nodes,
+// for comparison against BenchmarkMakeParallel10000.
+func BenchmarkMake10000(b *testing.B) {
+	makeSz(10000, b)
+}
+
+// BenchmarkMake100000 benchmarks the sequential New with 100,000
+// nodes, for comparison against BenchmarkMakeParallel100000.
+func BenchmarkMake100000(b *testing.B) {
+	makeSz(100000, b)
+}
+
+// BenchmarkMake1000000 benchmarks the sequential New with 1,000,000
+// nodes, for comparison against BenchmarkMakeParallel1000000.
+func BenchmarkMake1000000(b *testing.B) {
+	makeSz(1000000, b)
+}
+
+// makeParallelSz benchmarks NewParallel with a given number of nodes.
+// The time includes allocating the nodes.
+func makeParallelSz(sz int, b *testing.B) {
+	b.StopTimer()
+	pts := make([]Point, sz)
+	for i := range pts {
+		for j := range pts[i] {
+			pts[i][j] = rand.Float64()
+		}
+	}
+
+	b.StartTimer()
+	nodes := make([]T, sz)
+	nodeps := make([]*T, sz)
+	for i := range nodes {
+		nodes[i].Point = pts[i]
+		nodeps[i] = &nodes[i]
+	}
+
+	for i := 0; i < b.N; i++ {
+		NewParallel(nodeps, 0)
+	}
+}
+
 func BenchmarkMakeInRange1000(b *testing.B) {
 	newInRangeSz(1000, b)
 }
@@ -0,0 +1,89 @@
+package kdtree
+
+// Delete removes the node with point pt from the tree rooted at t and
+// returns the (possibly new) root of the resulting tree, plus whether a
+// node was removed. If multiple nodes share the same point, one of
+// them is removed arbitrarily.
+func (t *T) Delete(pt Point) (*T, bool) {
+	if t == nil {
+		return nil, false
+	}
+	if t.Point == pt {
+		return t.deleteSelf(), true
+	}
+	if pt[t.split] < t.Point[t.split] {
+		left, ok := t.left.Delete(pt)
+		t.left = left
+		return t, ok
+	}
+	right, ok := t.right.Delete(pt)
+	t.right = right
+	return t, ok
+}
+
+// deleteSelf removes t itself and returns the root of the resulting
+// subtree, using the classical k-d tree deletion: replace t's point
+// with its in-order successor on t's splitting dimension -- the
+// minimum of that dimension in the right subtree, or of the left
+// subtree if the right is empty -- and recursively delete the
+// successor from the subtree it came from.
+func (t *T) deleteSelf() *T {
+	switch {
+	case t.right != nil:
+		succ := t.right.findMin(t.split)
+		t.Point = succ.Point
+		t.right, _ = t.right.Delete(succ.Point)
+	case t.left != nil:
+		succ := t.left.findMin(t.split)
+		t.Point = succ.Point
+		t.right, _ = t.left.Delete(succ.Point)
+		t.left = nil
+	default:
+		return nil
+	}
+	return t
+}
+
+// findMin returns the node with the minimum coordinate on dimension d
+// within the subtree rooted at t. At a node that itself splits on d,
+// the minimum can only be in the left subtree (or be the node itself),
+// since the right subtree holds only values >= the node's; at any
+// other split, both subtrees must be searched.
+func (t *T) findMin(d int) *T {
+	if t == nil {
+		return nil
+	}
+	min := t
+	if t.split == d {
+		if l := t.left.findMin(d); l != nil {
+			min = l
+		}
+		return min
+	}
+	if l := t.left.findMin(d); l != nil && l.Point[d] < min.Point[d] {
+		min = l
+	}
+	if r := t.right.findMin(d); r != nil && r.Point[d] < min.Point[d] {
+		min = r
+	}
+	return min
+}
+
+// Rebuild collects every node in the tree rooted at t and rebuilds a
+// balanced tree out of them via New, returning its root. Use Rebuild
+// to restore balance after many Inserts and Deletes have skewed the
+// tree.
+func (t *T) Rebuild() *T {
+	return New(t.collect(nil))
+}
+
+// collect appends every node in the tree rooted at t to buf, in order.
+func (t *T) collect(buf []*T) []*T {
+	if t == nil {
+		return buf
+	}
+	buf = t.left.collect(buf)
+	buf = append(buf, t)
+	buf = t.right.collect(buf)
+	return buf
+}
@@ -0,0 +1,55 @@
+package kdtree
+
+import (
+	"testing"
+	"testing/quick"
+)
+
+// TestDelete tests the Delete function, ensuring that deleting every
+// point from a tree one at a time always succeeds, leaves the k-d tree
+// invariant intact, and shrinks the tree by exactly one node each time.
+func TestDelete(t *testing.T) {
+	if err := quick.Check(func(pts pointSlice) bool {
+		nodes := make([]*T, len(pts))
+		for i, pt := range pts {
+			nodes[i] = &T{Point: pt}
+		}
+		tree := New(nodes)
+
+		remaining := len(pts)
+		for _, pt := range pts {
+			var ok bool
+			tree, ok = tree.Delete(pt)
+			if !ok {
+				return false
+			}
+			remaining--
+
+			got, inv := tree.invariantHolds()
+			if !inv || len(got) != remaining {
+				return false
+			}
+		}
+		return tree == nil
+	}, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestRebuild tests that Rebuild preserves every point and restores
+// the k-d tree invariant after a tree has been grown with Insert,
+// which does not balance as it goes.
+func TestRebuild(t *testing.T) {
+	if err := quick.Check(func(pts pointSlice) bool {
+		var tree *T
+		for _, pt := range pts {
+			tree = tree.Insert(&T{Point: pt})
+		}
+
+		tree = tree.Rebuild()
+		got, ok := tree.invariantHolds()
+		return ok && len(got) == len(pts)
+	}, nil); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,67 @@
+package kdtree
+
+// InBox returns every node in the tree rooted at t whose point lies
+// within the axis-aligned box [min, max] (inclusive), appending to and
+// returning buf. Passing a buf with spare capacity (or a prior result
+// sliced to buf[:0]) avoids an allocation per call, mirroring InRange.
+func (t *T) InBox(min, max Point, buf []*T) []*T {
+	return t.inBox(min, max, infBox(), buf)
+}
+
+// inBox recurses over the tree rooted at t, where b is t's own bounding
+// box tracked from the root. A subtree is pruned as soon as [min, max]
+// falls entirely on one side of its splitting hyperplane, and emitted
+// in full, without any further point comparisons, as soon as b is
+// wholly contained in [min, max].
+func (t *T) inBox(min, max Point, b box, buf []*T) []*T {
+	if t == nil {
+		return buf
+	}
+	if b.containedIn(min, max) {
+		return t.emit(buf)
+	}
+
+	if containsPoint(min, max, &t.Point) {
+		buf = append(buf, t)
+	}
+
+	d := t.split
+	if min[d] < t.Point[d] {
+		buf = t.left.inBox(min, max, b.child(t, true), buf)
+	}
+	if max[d] >= t.Point[d] {
+		buf = t.right.inBox(min, max, b.child(t, false), buf)
+	}
+	return buf
+}
+
+// emit appends every node in the subtree rooted at t to buf.
+func (t *T) emit(buf []*T) []*T {
+	if t == nil {
+		return buf
+	}
+	buf = append(buf, t)
+	buf = t.left.emit(buf)
+	buf = t.right.emit(buf)
+	return buf
+}
+
+// containedIn reports whether b is wholly contained in [min, max].
+func (b box) containedIn(min, max Point) bool {
+	for i := range min {
+		if b.min[i] < min[i] || b.max[i] > max[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// containsPoint reports whether pt lies within [min, max].
+func containsPoint(min, max Point, pt *Point) bool {
+	for i := range pt {
+		if pt[i] < min[i] || pt[i] > max[i] {
+			return false
+		}
+	}
+	return true
+}
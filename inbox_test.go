@@ -0,0 +1,45 @@
+package kdtree
+
+import (
+	"testing"
+	"testing/quick"
+)
+
+// TestInBox tests the InBox function, ensuring that all points in the
+// box are reported, and all points reported are indeed in the box.
+func TestInBox(t *testing.T) {
+	if err := quick.Check(func(pts pointSlice, a, c Point) bool {
+		var min, max Point
+		for i := range min {
+			if a[i] < c[i] {
+				min[i], max[i] = a[i], c[i]
+			} else {
+				min[i], max[i] = c[i], a[i]
+			}
+		}
+
+		nodes := make([]*T, len(pts))
+		for i, pt := range pts {
+			nodes[i] = &T{Point: pt}
+		}
+
+		tree := New(nodes)
+		in := make(map[*T]bool, len(nodes))
+		for _, n := range tree.InBox(min, max, nil) {
+			in[n] = true
+		}
+
+		num := 0
+		for _, n := range nodes {
+			if containsPoint(min, max, &n.Point) {
+				num++
+				if !in[n] {
+					return false
+				}
+			}
+		}
+		return num == len(in)
+	}, nil); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,172 @@
+// Package kdtree implements a k-d tree: a binary tree that recursively
+// partitions K-dimensional space on alternating dimensions, giving fast
+// nearest-neighbor and range queries over a static or slowly-changing
+// set of points.
+package kdtree
+
+import "sort"
+
+// K is the number of dimensions of a Point. To work with a different
+// number of dimensions, use TreeN instead, which establishes its
+// dimensionality at construction rather than compile time.
+const K = 2
+
+// Point is a point in K-dimensional space.
+type Point [K]float64
+
+// sqDist returns the squared Euclidean distance between p and q. The
+// squared distance is used throughout this package instead of the
+// distance itself, since it avoids a sqrt on every comparison and
+// preserves ordering.
+func (p *Point) sqDist(q *Point) float64 {
+	var d float64
+	for i := range p {
+		diff := p[i] - q[i]
+		d += diff * diff
+	}
+	return d
+}
+
+// T is a node of a k-d tree. The nil *T is the empty tree, so a tree
+// can be grown from its zero value by repeated calls to Insert.
+type T struct {
+	Point       Point
+	left, right *T
+	split       int
+}
+
+// Insert inserts n into the tree rooted at t and returns the (possibly
+// new) root of the resulting tree. Insert does not rebalance, so trees
+// built from already-sorted input can become skewed; use New to build
+// a balanced tree from a known set of nodes instead.
+func (t *T) Insert(n *T) *T {
+	return t.insert(n, 0)
+}
+
+func (t *T) insert(n *T, depth int) *T {
+	if t == nil {
+		n.split = depth % K
+		n.left, n.right = nil, nil
+		return n
+	}
+	if n.Point[t.split] < t.Point[t.split] {
+		t.left = t.left.insert(n, depth+1)
+	} else {
+		t.right = t.right.insert(n, depth+1)
+	}
+	return t
+}
+
+// New builds a balanced k-d tree out of nodes and returns its root.
+// Unlike repeated calls to Insert, New chooses the median node on each
+// dimension as it recurses, so the resulting tree has depth O(log n)
+// regardless of the input order. New reorders and reuses nodes; callers
+// should not use the slice afterward.
+func New(nodes []*T) *T {
+	if len(nodes) == 0 {
+		return nil
+	}
+	return build(preSort(nodes), 0)
+}
+
+// build recurses over a preSorted set of nodes, picking the median node
+// on the dimension for depth at each step.
+func build(p preSorted, depth int) *T {
+	if p.Len() == 0 {
+		return nil
+	}
+	dim := depth % K
+	med, left, right := p.splitMed(dim)
+	med.split = dim
+	med.left = build(left, depth+1)
+	med.right = build(right, depth+1)
+	return med
+}
+
+// preSorted holds, for every dimension, the same set of nodes sorted by
+// that dimension's coordinate. Keeping all K orderings around lets
+// splitMed partition in linear time instead of re-sorting at every
+// level of the tree.
+type preSorted struct {
+	cur [K][]*T
+}
+
+// Len returns the number of nodes held by p.
+func (p preSorted) Len() int {
+	return len(p.cur[0])
+}
+
+// preSort returns nodes sorted on every dimension.
+func preSort(nodes []*T) preSorted {
+	var p preSorted
+	for d := range p.cur {
+		cur := make([]*T, len(nodes))
+		copy(cur, nodes)
+		dim := d
+		sort.Slice(cur, func(i, j int) bool {
+			return cur[i].Point[dim] < cur[j].Point[dim]
+		})
+		p.cur[d] = cur
+	}
+	return p
+}
+
+// splitMed picks the median node of p on dim and partitions the
+// remaining nodes into left and right, where left holds every node
+// whose dim coordinate is strictly less than the median's, and right
+// holds the rest. Ties with the median's value land in right, which
+// keeps the split well-defined (see issue 18) and matches the subtree
+// invariant checked by invariantHolds. The relative order of nodes
+// within each dimension is preserved, so left and right are themselves
+// valid preSorted sets.
+func (p preSorted) splitMed(dim int) (med *T, left, right preSorted) {
+	med = p.cur[dim][p.Len()/2]
+	medVal := med.Point[dim]
+
+	for d := range p.cur {
+		cur := p.cur[d]
+		l := make([]*T, 0, len(cur))
+		r := make([]*T, 0, len(cur))
+		for _, n := range cur {
+			if n == med {
+				continue
+			}
+			if n.Point[dim] < medVal {
+				l = append(l, n)
+			} else {
+				r = append(r, n)
+			}
+		}
+		left.cur[d] = l
+		right.cur[d] = r
+	}
+	return med, left, right
+}
+
+// InRange returns every node in the tree rooted at t whose point lies
+// within r of pt, appending to and returning buf. Passing a buf with
+// spare capacity (or a prior result sliced to buf[:0]) avoids an
+// allocation per call.
+func (t *T) InRange(pt Point, r float64, buf []*T) []*T {
+	if t == nil {
+		return buf
+	}
+	rr := r * r
+	if t.Point.sqDist(&pt) <= rr {
+		buf = append(buf, t)
+	}
+
+	diff := pt[t.split] - t.Point[t.split]
+	if diff <= 0 {
+		buf = t.left.InRange(pt, r, buf)
+		if diff*diff <= rr {
+			buf = t.right.InRange(pt, r, buf)
+		}
+	} else {
+		buf = t.right.InRange(pt, r, buf)
+		if diff*diff <= rr {
+			buf = t.left.InRange(pt, r, buf)
+		}
+	}
+	return buf
+}
@@ -0,0 +1,155 @@
+package kdtree
+
+import (
+	"container/heap"
+	"math"
+)
+
+// box is an axis-aligned bounding box, used to compute a lower bound on
+// the distance from a query point to any point held within a subtree.
+type box struct {
+	min, max Point
+}
+
+// infBox returns a box covering all of K-dimensional space.
+func infBox() box {
+	var b box
+	for d := range b.min {
+		b.min[d] = math.Inf(-1)
+		b.max[d] = math.Inf(1)
+	}
+	return b
+}
+
+// child returns the bounding box for n's left or right subtree, given
+// n's own box, by tightening the box on n's splitting dimension.
+func (b box) child(n *T, left bool) box {
+	if left {
+		b.max[n.split] = n.Point[n.split]
+	} else {
+		b.min[n.split] = n.Point[n.split]
+	}
+	return b
+}
+
+// lowerBoundSqDist returns a lower bound on the squared distance from
+// pt to any point contained in b.
+func (b box) lowerBoundSqDist(pt *Point) float64 {
+	var d float64
+	for i := range pt {
+		switch {
+		case pt[i] < b.min[i]:
+			diff := b.min[i] - pt[i]
+			d += diff * diff
+		case pt[i] > b.max[i]:
+			diff := pt[i] - b.max[i]
+			d += diff * diff
+		}
+	}
+	return d
+}
+
+// subtreeEntry is an item in the best-first search's min-heap: a
+// subtree rooted at node, together with a lower bound on the distance
+// from the query point to any point it might hold.
+type subtreeEntry struct {
+	node *T
+	box  box
+	lb   float64
+}
+
+type subtreeHeap []subtreeEntry
+
+func (h subtreeHeap) Len() int            { return len(h) }
+func (h subtreeHeap) Less(i, j int) bool  { return h[i].lb < h[j].lb }
+func (h subtreeHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *subtreeHeap) Push(x interface{}) { *h = append(*h, x.(subtreeEntry)) }
+func (h *subtreeHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	*h = old[:n-1]
+	return e
+}
+
+// candidate is an item in the KNN search's bounded max-heap of the best
+// nodes found so far, ordered so the current k-th best sits at the top.
+type candidate struct {
+	node *T
+	d    float64
+}
+
+type candidateHeap []candidate
+
+func (h candidateHeap) Len() int            { return len(h) }
+func (h candidateHeap) Less(i, j int) bool  { return h[i].d > h[j].d }
+func (h candidateHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *candidateHeap) Push(x interface{}) { *h = append(*h, x.(candidate)) }
+func (h *candidateHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	*h = old[:n-1]
+	return e
+}
+
+// KNN returns the k nodes in the tree rooted at t closest to pt, in
+// ascending order of distance, appending to and returning buf. Passing
+// a buf with spare capacity (or a prior result sliced to buf[:0])
+// avoids an allocation per call, mirroring InRange.
+func (t *T) KNN(pt Point, k int, buf []*T) []*T {
+	return t.KNNFunc(pt, k, buf, nil)
+}
+
+// KNNFunc is like KNN but only considers nodes for which accept returns
+// true; accept may be nil to consider every node.
+//
+// The search is a best-first traversal: a min-heap of subtrees, keyed
+// on a lower bound distance from pt to the subtree's bounding box, and
+// a bounded max-heap of the k best candidates found so far. At each
+// step the subtree with the smallest lower bound is popped; its own
+// point is tested against the candidate heap, and its children are
+// pushed with tightened boxes. The search stops as soon as the
+// smallest remaining lower bound exceeds the k-th best distance found.
+func (t *T) KNNFunc(pt Point, k int, buf []*T, accept func(*T) bool) []*T {
+	if t == nil || k <= 0 {
+		return buf
+	}
+
+	subtrees := &subtreeHeap{{node: t, box: infBox()}}
+	var best candidateHeap
+
+	for subtrees.Len() > 0 {
+		e := heap.Pop(subtrees).(subtreeEntry)
+		if best.Len() == k && e.lb > best[0].d {
+			break
+		}
+
+		n := e.node
+		if accept == nil || accept(n) {
+			d := pt.sqDist(&n.Point)
+			switch {
+			case best.Len() < k:
+				heap.Push(&best, candidate{n, d})
+			case d < best[0].d:
+				heap.Pop(&best)
+				heap.Push(&best, candidate{n, d})
+			}
+		}
+
+		if n.left != nil {
+			cb := e.box.child(n, true)
+			heap.Push(subtrees, subtreeEntry{n.left, cb, cb.lowerBoundSqDist(&pt)})
+		}
+		if n.right != nil {
+			cb := e.box.child(n, false)
+			heap.Push(subtrees, subtreeEntry{n.right, cb, cb.lowerBoundSqDist(&pt)})
+		}
+	}
+
+	results := make([]*T, best.Len())
+	for i := len(results) - 1; i >= 0; i-- {
+		results[i] = heap.Pop(&best).(candidate).node
+	}
+	return append(buf, results...)
+}
@@ -0,0 +1,85 @@
+package kdtree
+
+import (
+	"sort"
+	"testing"
+	"testing/quick"
+)
+
+// TestKNN tests the KNN function, ensuring that it returns the k
+// closest nodes to pt in ascending order of distance, agreeing with a
+// linear scan.
+func TestKNN(t *testing.T) {
+	if err := quick.Check(func(pts pointSlice, pt Point, k uint8) bool {
+		nodes := make([]*T, len(pts))
+		for i, p := range pts {
+			nodes[i] = &T{Point: p}
+		}
+		tree := New(nodes)
+
+		want := append([]*T{}, nodes...)
+		sort.Slice(want, func(i, j int) bool {
+			return pt.sqDist(&want[i].Point) < pt.sqDist(&want[j].Point)
+		})
+		if int(k) < len(want) {
+			want = want[:k]
+		}
+
+		got := tree.KNN(pt, int(k), nil)
+		if len(got) != len(want) {
+			return false
+		}
+		for i := range got {
+			if pt.sqDist(&got[i].Point) != pt.sqDist(&want[i].Point) {
+				return false
+			}
+		}
+		return true
+	}, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestKNNFunc tests KNNFunc with a non-trivial accept predicate,
+// ensuring that it returns the k closest nodes among those accepted,
+// agreeing with a filtered linear scan.
+func TestKNNFunc(t *testing.T) {
+	accept := func(n *T) bool { return n.Point[0] >= 0.5 }
+
+	if err := quick.Check(func(pts pointSlice, pt Point, k uint8) bool {
+		nodes := make([]*T, len(pts))
+		for i, p := range pts {
+			nodes[i] = &T{Point: p}
+		}
+		tree := New(nodes)
+
+		want := make([]*T, 0, len(nodes))
+		for _, n := range nodes {
+			if accept(n) {
+				want = append(want, n)
+			}
+		}
+		sort.Slice(want, func(i, j int) bool {
+			return pt.sqDist(&want[i].Point) < pt.sqDist(&want[j].Point)
+		})
+		if int(k) < len(want) {
+			want = want[:k]
+		}
+
+		got := tree.KNNFunc(pt, int(k), nil, accept)
+		if len(got) != len(want) {
+			return false
+		}
+		for i := range got {
+			if !accept(got[i]) {
+				return false
+			}
+			if pt.sqDist(&got[i].Point) != pt.sqDist(&want[i].Point) {
+				return false
+			}
+		}
+		return true
+	}, nil); err != nil {
+		t.Error(err)
+	}
+}
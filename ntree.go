@@ -0,0 +1,385 @@
+package kdtree
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+)
+
+// PointN is a point in a space of runtime-determined dimensionality.
+// It is the PointN analogue of Point, for callers whose dimensionality
+// isn't known until the tree is built -- 3D scans, 6D robotics state,
+// or high-dimensional embeddings, say -- and who would otherwise have
+// to fork this package to change K.
+type PointN []float64
+
+// sqDist returns the squared Euclidean distance between p and q, which
+// must have the same length.
+func (p PointN) sqDist(q PointN) float64 {
+	var d float64
+	for i := range p {
+		diff := p[i] - q[i]
+		d += diff * diff
+	}
+	return d
+}
+
+// TN is a node of a TreeN.
+type TN struct {
+	Point       PointN
+	left, right *TN
+	split       int
+}
+
+// TreeN is a k-d tree over points of dimensionality fixed at
+// construction time by NewN, rather than the compile-time constant K
+// used by T. Every node inserted into or returned by a TreeN shares
+// that dimensionality.
+type TreeN struct {
+	dims int
+	root *TN
+}
+
+// Dims returns the dimensionality t was constructed with.
+func (t *TreeN) Dims() int {
+	return t.dims
+}
+
+// checkDims panics unless p has the same length as t.dims; every
+// method taking a PointN calls this so a caller gets the same clear
+// panic message regardless of whether the mismatch is a wrong-sized
+// insert or a wrong-sized query.
+func (t *TreeN) checkDims(p PointN) {
+	if len(p) != t.dims {
+		panic("kdtree: PointN has wrong length for this TreeN")
+	}
+}
+
+// NewN builds a balanced TreeN of the given dimensionality out of
+// nodes and returns it. dims must be positive, and every node in nodes
+// must have a Point of length dims; NewN panics otherwise. NewN
+// reorders and reuses nodes; callers should not use the slice
+// afterward.
+func NewN(dims int, nodes []*TN) *TreeN {
+	if dims <= 0 {
+		panic("kdtree: TreeN dims must be positive")
+	}
+	for _, n := range nodes {
+		if len(n.Point) != dims {
+			panic("kdtree: PointN has wrong length for this TreeN")
+		}
+	}
+	return &TreeN{dims: dims, root: buildN(preSortN(nodes, dims), dims, 0)}
+}
+
+// Insert inserts n into t and returns t. n.Point must have length
+// t.Dims(); Insert panics otherwise. Insert does not rebalance, so
+// trees grown from already-sorted input can become skewed; use NewN
+// to build a balanced tree from a known set of nodes instead.
+func (t *TreeN) Insert(n *TN) *TreeN {
+	t.checkDims(n.Point)
+	t.root = t.root.insert(n, t.dims, 0)
+	return t
+}
+
+func (n *TN) insert(m *TN, dims, depth int) *TN {
+	if n == nil {
+		m.split = depth % dims
+		m.left, m.right = nil, nil
+		return m
+	}
+	if m.Point[n.split] < n.Point[n.split] {
+		n.left = n.left.insert(m, dims, depth+1)
+	} else {
+		n.right = n.right.insert(m, dims, depth+1)
+	}
+	return n
+}
+
+// preSortedN is the PointN analogue of preSorted: nodes sorted by
+// every dimension, with the number of dimensions fixed at runtime.
+type preSortedN struct {
+	cur [][]*TN
+}
+
+func (p preSortedN) Len() int {
+	return len(p.cur[0])
+}
+
+func preSortN(nodes []*TN, dims int) preSortedN {
+	p := preSortedN{cur: make([][]*TN, dims)}
+	for d := 0; d < dims; d++ {
+		cur := make([]*TN, len(nodes))
+		copy(cur, nodes)
+		dim := d
+		sort.Slice(cur, func(i, j int) bool {
+			return cur[i].Point[dim] < cur[j].Point[dim]
+		})
+		p.cur[d] = cur
+	}
+	return p
+}
+
+// splitMed is the preSortedN analogue of preSorted.splitMed; see its
+// doc comment for the tie-breaking rule.
+func (p preSortedN) splitMed(dim int) (med *TN, left, right preSortedN) {
+	med = p.cur[dim][p.Len()/2]
+	medVal := med.Point[dim]
+
+	left.cur = make([][]*TN, len(p.cur))
+	right.cur = make([][]*TN, len(p.cur))
+	for d := range p.cur {
+		cur := p.cur[d]
+		l := make([]*TN, 0, len(cur))
+		r := make([]*TN, 0, len(cur))
+		for _, n := range cur {
+			if n == med {
+				continue
+			}
+			if n.Point[dim] < medVal {
+				l = append(l, n)
+			} else {
+				r = append(r, n)
+			}
+		}
+		left.cur[d] = l
+		right.cur[d] = r
+	}
+	return med, left, right
+}
+
+func buildN(p preSortedN, dims, depth int) *TN {
+	if p.Len() == 0 {
+		return nil
+	}
+	dim := depth % dims
+	med, left, right := p.splitMed(dim)
+	med.split = dim
+	med.left = buildN(left, dims, depth+1)
+	med.right = buildN(right, dims, depth+1)
+	return med
+}
+
+// InRange returns every node in t whose point lies within r of pt,
+// appending to and returning buf. pt must have length t.Dims();
+// InRange panics otherwise.
+func (t *TreeN) InRange(pt PointN, r float64, buf []*TN) []*TN {
+	t.checkDims(pt)
+	return t.root.inRange(pt, r, buf)
+}
+
+func (n *TN) inRange(pt PointN, r float64, buf []*TN) []*TN {
+	if n == nil {
+		return buf
+	}
+	rr := r * r
+	if n.Point.sqDist(pt) <= rr {
+		buf = append(buf, n)
+	}
+
+	diff := pt[n.split] - n.Point[n.split]
+	if diff <= 0 {
+		buf = n.left.inRange(pt, r, buf)
+		if diff*diff <= rr {
+			buf = n.right.inRange(pt, r, buf)
+		}
+	} else {
+		buf = n.right.inRange(pt, r, buf)
+		if diff*diff <= rr {
+			buf = n.left.inRange(pt, r, buf)
+		}
+	}
+	return buf
+}
+
+// boxN is the PointN analogue of box.
+type boxN struct {
+	min, max PointN
+}
+
+func infBoxN(dims int) boxN {
+	b := boxN{min: make(PointN, dims), max: make(PointN, dims)}
+	for d := 0; d < dims; d++ {
+		b.min[d] = math.Inf(-1)
+		b.max[d] = math.Inf(1)
+	}
+	return b
+}
+
+func (b boxN) child(n *TN, left bool) boxN {
+	nb := boxN{min: append(PointN(nil), b.min...), max: append(PointN(nil), b.max...)}
+	if left {
+		nb.max[n.split] = n.Point[n.split]
+	} else {
+		nb.min[n.split] = n.Point[n.split]
+	}
+	return nb
+}
+
+func (b boxN) lowerBoundSqDist(pt PointN) float64 {
+	var d float64
+	for i := range pt {
+		switch {
+		case pt[i] < b.min[i]:
+			diff := b.min[i] - pt[i]
+			d += diff * diff
+		case pt[i] > b.max[i]:
+			diff := pt[i] - b.max[i]
+			d += diff * diff
+		}
+	}
+	return d
+}
+
+func (b boxN) containedIn(min, max PointN) bool {
+	for i := range min {
+		if b.min[i] < min[i] || b.max[i] > max[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func containsPointN(min, max, pt PointN) bool {
+	for i := range pt {
+		if pt[i] < min[i] || pt[i] > max[i] {
+			return false
+		}
+	}
+	return true
+}
+
+type subtreeEntryN struct {
+	node *TN
+	box  boxN
+	lb   float64
+}
+
+type subtreeHeapN []subtreeEntryN
+
+func (h subtreeHeapN) Len() int            { return len(h) }
+func (h subtreeHeapN) Less(i, j int) bool  { return h[i].lb < h[j].lb }
+func (h subtreeHeapN) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *subtreeHeapN) Push(x interface{}) { *h = append(*h, x.(subtreeEntryN)) }
+func (h *subtreeHeapN) Pop() interface{} {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	*h = old[:n-1]
+	return e
+}
+
+type candidateN struct {
+	node *TN
+	d    float64
+}
+
+type candidateHeapN []candidateN
+
+func (h candidateHeapN) Len() int            { return len(h) }
+func (h candidateHeapN) Less(i, j int) bool  { return h[i].d > h[j].d }
+func (h candidateHeapN) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *candidateHeapN) Push(x interface{}) { *h = append(*h, x.(candidateN)) }
+func (h *candidateHeapN) Pop() interface{} {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	*h = old[:n-1]
+	return e
+}
+
+// KNN returns the k nodes in t closest to pt, in ascending order of
+// distance, appending to and returning buf. It is the TreeN analogue
+// of T.KNN; see its doc comment for the search strategy.
+func (t *TreeN) KNN(pt PointN, k int, buf []*TN) []*TN {
+	return t.KNNFunc(pt, k, buf, nil)
+}
+
+// KNNFunc is like KNN but only considers nodes for which accept
+// returns true; accept may be nil to consider every node. pt must
+// have length t.Dims(); KNNFunc panics otherwise.
+func (t *TreeN) KNNFunc(pt PointN, k int, buf []*TN, accept func(*TN) bool) []*TN {
+	t.checkDims(pt)
+	if t.root == nil || k <= 0 {
+		return buf
+	}
+
+	subtrees := &subtreeHeapN{{node: t.root, box: infBoxN(t.dims)}}
+	var best candidateHeapN
+
+	for subtrees.Len() > 0 {
+		e := heap.Pop(subtrees).(subtreeEntryN)
+		if best.Len() == k && e.lb > best[0].d {
+			break
+		}
+
+		n := e.node
+		if accept == nil || accept(n) {
+			d := pt.sqDist(n.Point)
+			switch {
+			case best.Len() < k:
+				heap.Push(&best, candidateN{n, d})
+			case d < best[0].d:
+				heap.Pop(&best)
+				heap.Push(&best, candidateN{n, d})
+			}
+		}
+
+		if n.left != nil {
+			cb := e.box.child(n, true)
+			heap.Push(subtrees, subtreeEntryN{n.left, cb, cb.lowerBoundSqDist(pt)})
+		}
+		if n.right != nil {
+			cb := e.box.child(n, false)
+			heap.Push(subtrees, subtreeEntryN{n.right, cb, cb.lowerBoundSqDist(pt)})
+		}
+	}
+
+	results := make([]*TN, best.Len())
+	for i := len(results) - 1; i >= 0; i-- {
+		results[i] = heap.Pop(&best).(candidateN).node
+	}
+	return append(buf, results...)
+}
+
+// InBox returns every node in t whose point lies within the
+// axis-aligned box [min, max] (inclusive), appending to and returning
+// buf. It is the TreeN analogue of T.InBox. min and max must each have
+// length t.Dims(); InBox panics otherwise.
+func (t *TreeN) InBox(min, max PointN, buf []*TN) []*TN {
+	t.checkDims(min)
+	t.checkDims(max)
+	return t.root.inBox(min, max, infBoxN(t.dims), buf)
+}
+
+func (n *TN) inBox(min, max PointN, b boxN, buf []*TN) []*TN {
+	if n == nil {
+		return buf
+	}
+	if b.containedIn(min, max) {
+		return n.emit(buf)
+	}
+
+	if containsPointN(min, max, n.Point) {
+		buf = append(buf, n)
+	}
+
+	d := n.split
+	if min[d] < n.Point[d] {
+		buf = n.left.inBox(min, max, b.child(n, true), buf)
+	}
+	if max[d] >= n.Point[d] {
+		buf = n.right.inBox(min, max, b.child(n, false), buf)
+	}
+	return buf
+}
+
+func (n *TN) emit(buf []*TN) []*TN {
+	if n == nil {
+		return buf
+	}
+	buf = append(buf, n)
+	buf = n.left.emit(buf)
+	buf = n.right.emit(buf)
+	return buf
+}
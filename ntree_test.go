@@ -0,0 +1,300 @@
+package kdtree
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"testing/quick"
+)
+
+// ndims is the dimensionality used to exercise TreeN in tests.
+const ndims = 5
+
+// pointNSlice is a slice of PointN that implements the quick.Generator
+// interface, generating random points of length ndims in the unit
+// hypercube.
+type pointNSlice []PointN
+
+func (pointNSlice) Generate(r *rand.Rand, size int) reflect.Value {
+	ps := make([]PointN, size)
+	for i := range ps {
+		ps[i] = make(PointN, ndims)
+		for j := range ps[i] {
+			ps[i][j] = r.Float64()
+		}
+	}
+	return reflect.ValueOf(ps)
+}
+
+// Generate implements the Generator interface for PointN.
+func (pointN) Generate(r *rand.Rand, _ int) reflect.Value {
+	p := make(PointN, ndims)
+	for i := range p {
+		p[i] = r.Float64()
+	}
+	return reflect.ValueOf(pointN(p))
+}
+
+// pointN wraps PointN so it can carry its own Generate method without
+// colliding with pointNSlice's element type.
+type pointN PointN
+
+// TestInsertN tests TreeN.Insert, ensuring that random points inserted
+// into an empty tree maintain the k-d tree invariant.
+func TestInsertN(t *testing.T) {
+	if err := quick.Check(func(pts pointNSlice) bool {
+		tree := NewN(ndims, nil)
+		for _, p := range pts {
+			tree.Insert(&TN{Point: p})
+		}
+		_, ok := tree.root.invariantHoldsN()
+		return ok
+	}, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestMakeN tests NewN, ensuring that a tree built using random points
+// respects the k-d tree invariant.
+func TestMakeN(t *testing.T) {
+	if err := quick.Check(func(pts pointNSlice) bool {
+		nodes := make([]*TN, len(pts))
+		for i, pt := range pts {
+			nodes[i] = &TN{Point: pt}
+		}
+		tree := NewN(ndims, nodes)
+		_, ok := tree.root.invariantHoldsN()
+		return ok
+	}, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+// invariantHoldsN is the TN analogue of T.invariantHolds.
+func (n *TN) invariantHoldsN() ([]PointN, bool) {
+	if n == nil {
+		return []PointN{}, true
+	}
+
+	left, leftOk := n.left.invariantHoldsN()
+	right, rightOk := n.right.invariantHoldsN()
+
+	ok := leftOk && rightOk
+
+	if ok {
+		for _, l := range left {
+			if l[n.split] >= n.Point[n.split] {
+				ok = false
+				break
+			}
+		}
+	}
+	if ok {
+		for _, r := range right {
+			if r[n.split] < n.Point[n.split] {
+				ok = false
+				break
+			}
+		}
+	}
+	return append(append(left, n.Point), right...), ok
+}
+
+// TestInRangeN tests TreeN.InRange, ensuring that all points in range
+// are reported, and all points reported are indeed in range.
+func TestInRangeN(t *testing.T) {
+	if err := quick.Check(func(pts pointNSlice, pt pointN, r float64) bool {
+		r = math.Abs(r)
+		nodes := make([]*TN, len(pts))
+		for i, p := range pts {
+			nodes[i] = &TN{Point: p}
+		}
+
+		tree := NewN(ndims, nodes)
+		in := make(map[*TN]bool, len(nodes))
+		for _, n := range tree.InRange(PointN(pt), r, nil) {
+			in[n] = true
+		}
+
+		num := 0
+		for _, n := range nodes {
+			if PointN(pt).sqDist(n.Point) <= r*r {
+				num++
+				if !in[n] {
+					return false
+				}
+			}
+		}
+		return num == len(in)
+	}, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestKNNN tests TreeN.KNN, ensuring it agrees with a linear scan.
+func TestKNNN(t *testing.T) {
+	if err := quick.Check(func(pts pointNSlice, pt pointN, k uint8) bool {
+		nodes := make([]*TN, len(pts))
+		for i, p := range pts {
+			nodes[i] = &TN{Point: p}
+		}
+		tree := NewN(ndims, nodes)
+
+		want := append([]*TN{}, nodes...)
+		sort.Slice(want, func(i, j int) bool {
+			return PointN(pt).sqDist(want[i].Point) < PointN(pt).sqDist(want[j].Point)
+		})
+		if int(k) < len(want) {
+			want = want[:k]
+		}
+
+		got := tree.KNN(PointN(pt), int(k), nil)
+		if len(got) != len(want) {
+			return false
+		}
+		for i := range got {
+			if PointN(pt).sqDist(got[i].Point) != PointN(pt).sqDist(want[i].Point) {
+				return false
+			}
+		}
+		return true
+	}, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestKNNFuncN tests TreeN.KNNFunc with a non-trivial accept
+// predicate, ensuring it agrees with a filtered linear scan.
+func TestKNNFuncN(t *testing.T) {
+	accept := func(n *TN) bool { return n.Point[0] >= 0.5 }
+
+	if err := quick.Check(func(pts pointNSlice, pt pointN, k uint8) bool {
+		nodes := make([]*TN, len(pts))
+		for i, p := range pts {
+			nodes[i] = &TN{Point: p}
+		}
+		tree := NewN(ndims, nodes)
+
+		want := make([]*TN, 0, len(nodes))
+		for _, n := range nodes {
+			if accept(n) {
+				want = append(want, n)
+			}
+		}
+		sort.Slice(want, func(i, j int) bool {
+			return PointN(pt).sqDist(want[i].Point) < PointN(pt).sqDist(want[j].Point)
+		})
+		if int(k) < len(want) {
+			want = want[:k]
+		}
+
+		got := tree.KNNFunc(PointN(pt), int(k), nil, accept)
+		if len(got) != len(want) {
+			return false
+		}
+		for i := range got {
+			if !accept(got[i]) {
+				return false
+			}
+			if PointN(pt).sqDist(got[i].Point) != PointN(pt).sqDist(want[i].Point) {
+				return false
+			}
+		}
+		return true
+	}, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestInBoxN tests TreeN.InBox, ensuring that all points in the box
+// are reported, and all points reported are indeed in the box.
+func TestInBoxN(t *testing.T) {
+	if err := quick.Check(func(pts pointNSlice, a, c pointN) bool {
+		min := make(PointN, ndims)
+		max := make(PointN, ndims)
+		for i := range min {
+			if a[i] < c[i] {
+				min[i], max[i] = a[i], c[i]
+			} else {
+				min[i], max[i] = c[i], a[i]
+			}
+		}
+
+		nodes := make([]*TN, len(pts))
+		for i, p := range pts {
+			nodes[i] = &TN{Point: p}
+		}
+
+		tree := NewN(ndims, nodes)
+		in := make(map[*TN]bool, len(nodes))
+		for _, n := range tree.InBox(min, max, nil) {
+			in[n] = true
+		}
+
+		num := 0
+		for _, n := range nodes {
+			if containsPointN(min, max, n.Point) {
+				num++
+				if !in[n] {
+					return false
+				}
+			}
+		}
+		return num == len(in)
+	}, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+// mustPanic calls f and fails the test unless it panics with one of
+// this package's own "kdtree: " messages, as opposed to a runtime
+// error such as an index out of range.
+func mustPanic(t *testing.T, name string, f func()) {
+	t.Helper()
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Errorf("%s: expected panic", name)
+			return
+		}
+		if msg, ok := r.(string); !ok || !strings.HasPrefix(msg, "kdtree: ") {
+			t.Errorf("%s: got panic %v, want a kdtree: message", name, r)
+		}
+	}()
+	f()
+}
+
+// TestNewNBadDims ensures NewN rejects a non-positive dimensionality
+// instead of panicking later with an opaque index-out-of-range error.
+func TestNewNBadDims(t *testing.T) {
+	mustPanic(t, "NewN(0, nil)", func() { NewN(0, nil) })
+	mustPanic(t, "NewN(-1, nil)", func() { NewN(-1, nil) })
+}
+
+// TestTreeNWrongLengthQuery ensures every TreeN query entry point
+// rejects a PointN whose length doesn't match the tree's dimensionality,
+// rather than panicking deep inside with an opaque index-out-of-range
+// error or silently truncating a too-long point.
+func TestTreeNWrongLengthQuery(t *testing.T) {
+	tree := NewN(ndims, []*TN{
+		{Point: make(PointN, ndims)},
+		{Point: make(PointN, ndims)},
+	})
+
+	short := make(PointN, ndims-1)
+	long := make(PointN, ndims+1)
+
+	mustPanic(t, "InRange short", func() { tree.InRange(short, 1, nil) })
+	mustPanic(t, "InRange long", func() { tree.InRange(long, 1, nil) })
+
+	mustPanic(t, "InBox short min", func() { tree.InBox(short, make(PointN, ndims), nil) })
+	mustPanic(t, "InBox short max", func() { tree.InBox(make(PointN, ndims), short, nil) })
+	mustPanic(t, "InBox long min", func() { tree.InBox(long, make(PointN, ndims), nil) })
+
+	mustPanic(t, "KNN short", func() { tree.KNN(short, 1, nil) })
+	mustPanic(t, "KNN long", func() { tree.KNN(long, 1, nil) })
+	mustPanic(t, "KNNFunc short", func() { tree.KNNFunc(short, 1, nil, nil) })
+}
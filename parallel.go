@@ -0,0 +1,63 @@
+package kdtree
+
+import (
+	"runtime"
+	"sync"
+)
+
+// parallelCutoff is the subtree size below which NewParallel falls
+// back to the sequential build path used by New; spawning a goroutine
+// for a subtree this small costs more than it saves.
+const parallelCutoff = 1024
+
+// NewParallel is like New but, for large inputs, dispatches independent
+// subtree builds to up to maxGoroutines workers. This can substantially
+// speed up construction of trees over point-cloud-sized inputs
+// (100k+ points) on multi-core machines. If maxGoroutines <= 0, it
+// defaults to runtime.GOMAXPROCS(0). NewParallel reorders and reuses
+// nodes; callers should not use the slice afterward.
+func NewParallel(nodes []*T, maxGoroutines int) *T {
+	if len(nodes) == 0 {
+		return nil
+	}
+	if maxGoroutines <= 0 {
+		maxGoroutines = runtime.GOMAXPROCS(0)
+	}
+	sem := make(chan struct{}, maxGoroutines)
+	return buildParallel(preSort(nodes), 0, sem)
+}
+
+// buildParallel is the parallel analogue of build. Once a node's
+// children have been split off by splitMed, the left and right
+// subtree builds are independent, so the left one is handed to a
+// worker whenever sem has a free slot; otherwise it falls back to
+// building both subtrees on the calling goroutine.
+func buildParallel(p preSorted, depth int, sem chan struct{}) *T {
+	if p.Len() == 0 {
+		return nil
+	}
+	if p.Len() < parallelCutoff {
+		return build(p, depth)
+	}
+
+	dim := depth % K
+	med, left, right := p.splitMed(dim)
+	med.split = dim
+
+	select {
+	case sem <- struct{}{}:
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			med.left = buildParallel(left, depth+1, sem)
+		}()
+		med.right = buildParallel(right, depth+1, sem)
+		wg.Wait()
+	default:
+		med.left = buildParallel(left, depth+1, sem)
+		med.right = buildParallel(right, depth+1, sem)
+	}
+	return med
+}
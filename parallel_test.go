@@ -0,0 +1,46 @@
+package kdtree
+
+import (
+	"math/rand"
+	"testing"
+	"testing/quick"
+)
+
+// TestMakeParallel tests NewParallel, ensuring that a tree built in
+// parallel respects the same k-d tree invariant as one built by New.
+func TestMakeParallel(t *testing.T) {
+	if err := quick.Check(func(pts pointSlice) bool {
+		nodes := make([]*T, len(pts))
+		for i, pt := range pts {
+			nodes[i] = &T{Point: pt}
+		}
+		tree := NewParallel(nodes, 2)
+		_, ok := tree.invariantHolds()
+		return ok
+	}, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestMakeParallelLarge exercises the parallel split path itself: it
+// builds a tree well above parallelCutoff, where NewParallel actually
+// dispatches subtree builds to worker goroutines.
+func TestMakeParallelLarge(t *testing.T) {
+	const sz = 4 * parallelCutoff
+	nodes := make([]*T, sz)
+	for i := range nodes {
+		nodes[i] = &T{}
+		for j := range nodes[i].Point {
+			nodes[i].Point[j] = rand.Float64()
+		}
+	}
+
+	tree := NewParallel(nodes, 4)
+	got, ok := tree.invariantHolds()
+	if !ok {
+		t.Fatal("invariant does not hold")
+	}
+	if len(got) != sz {
+		t.Fatalf("got %d points, want %d", len(got), sz)
+	}
+}
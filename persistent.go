@@ -0,0 +1,127 @@
+package kdtree
+
+// Persistent is an immutable, copy-on-write k-d tree. Insert and
+// Delete return a new Persistent that shares every subtree untouched
+// by the mutation with the receiver, rather than mutating the tree in
+// place as T's Insert and Delete do. Multiple versions of a Persistent
+// tree can therefore coexist and be queried concurrently by many
+// goroutines without locking, while a single writer produces new
+// versions -- useful for MVCC-style snapshots, undo/redo, or any
+// reader/writer split where readers must never see a half-applied
+// mutation. The zero Persistent is a valid, empty tree.
+type Persistent struct {
+	root *T
+}
+
+// NewPersistent builds a Persistent k-d tree from nodes via New. As
+// with New, nodes is reordered and reused, and should not be used
+// afterward.
+func NewPersistent(nodes []*T) Persistent {
+	return Persistent{root: New(nodes)}
+}
+
+// Insert returns a new Persistent holding every point in p plus pt.
+// Only the nodes on the path from the root to the new node are
+// copied; every sibling subtree is shared with p.
+func (p Persistent) Insert(pt Point) Persistent {
+	return Persistent{root: p.root.insertPersistent(&T{Point: pt}, 0)}
+}
+
+func (t *T) insertPersistent(n *T, depth int) *T {
+	if t == nil {
+		n.split = depth % K
+		n.left, n.right = nil, nil
+		return n
+	}
+	cp := *t
+	if n.Point[t.split] < t.Point[t.split] {
+		cp.left = t.left.insertPersistent(n, depth+1)
+	} else {
+		cp.right = t.right.insertPersistent(n, depth+1)
+	}
+	return &cp
+}
+
+// Delete returns a new Persistent with the node at pt removed, plus
+// whether a node was found to remove; if none was found, it returns p
+// unchanged. As with Insert, only the nodes on the path to the removed
+// node, and to its in-order successor, are copied.
+func (p Persistent) Delete(pt Point) (Persistent, bool) {
+	root, ok := p.root.deletePersistent(pt)
+	if !ok {
+		return p, false
+	}
+	return Persistent{root: root}, true
+}
+
+func (t *T) deletePersistent(pt Point) (*T, bool) {
+	if t == nil {
+		return nil, false
+	}
+	if t.Point == pt {
+		return t.deleteSelfPersistent(), true
+	}
+	if pt[t.split] < t.Point[t.split] {
+		left, ok := t.left.deletePersistent(pt)
+		if !ok {
+			return t, false
+		}
+		cp := *t
+		cp.left = left
+		return &cp, true
+	}
+	right, ok := t.right.deletePersistent(pt)
+	if !ok {
+		return t, false
+	}
+	cp := *t
+	cp.right = right
+	return &cp, true
+}
+
+// deleteSelfPersistent is the copy-on-write analogue of T.deleteSelf.
+func (t *T) deleteSelfPersistent() *T {
+	switch {
+	case t.right != nil:
+		succ := t.right.findMin(t.split)
+		cp := *t
+		cp.Point = succ.Point
+		cp.right, _ = t.right.deletePersistent(succ.Point)
+		return &cp
+	case t.left != nil:
+		succ := t.left.findMin(t.split)
+		cp := *t
+		cp.Point = succ.Point
+		cp.right, _ = t.left.deletePersistent(succ.Point)
+		cp.left = nil
+		return &cp
+	default:
+		return nil
+	}
+}
+
+// InRange returns every node in p whose point lies within r of pt,
+// appending to and returning buf. See T.InRange.
+func (p Persistent) InRange(pt Point, r float64, buf []*T) []*T {
+	return p.root.InRange(pt, r, buf)
+}
+
+// InBox returns every node in p whose point lies within the
+// axis-aligned box [min, max], appending to and returning buf. See
+// T.InBox.
+func (p Persistent) InBox(min, max Point, buf []*T) []*T {
+	return p.root.InBox(min, max, buf)
+}
+
+// KNN returns the k nodes in p closest to pt, in ascending order of
+// distance, appending to and returning buf. See T.KNN.
+func (p Persistent) KNN(pt Point, k int, buf []*T) []*T {
+	return p.root.KNN(pt, k, buf)
+}
+
+// KNNFunc is like KNN but only considers nodes for which accept
+// returns true; accept may be nil to consider every node. See
+// T.KNNFunc.
+func (p Persistent) KNNFunc(pt Point, k int, buf []*T, accept func(*T) bool) []*T {
+	return p.root.KNNFunc(pt, k, buf, accept)
+}
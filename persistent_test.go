@@ -0,0 +1,98 @@
+package kdtree
+
+import (
+	"testing"
+	"testing/quick"
+)
+
+// TestPersistentInsert tests Persistent.Insert, ensuring that building
+// a tree up one point at a time maintains the k-d tree invariant.
+func TestPersistentInsert(t *testing.T) {
+	if err := quick.Check(func(pts pointSlice) bool {
+		var p Persistent
+		for _, pt := range pts {
+			p = p.Insert(pt)
+		}
+		_, ok := p.root.invariantHolds()
+		return ok
+	}, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestPersistentDelete tests Persistent.Delete, ensuring that deleting
+// every point one at a time always succeeds and leaves the invariant
+// intact.
+func TestPersistentDelete(t *testing.T) {
+	if err := quick.Check(func(pts pointSlice) bool {
+		var p Persistent
+		for _, pt := range pts {
+			p = p.Insert(pt)
+		}
+
+		remaining := len(pts)
+		for _, pt := range pts {
+			var ok bool
+			p, ok = p.Delete(pt)
+			if !ok {
+				return false
+			}
+			remaining--
+
+			got, inv := p.root.invariantHolds()
+			if !inv || len(got) != remaining {
+				return false
+			}
+		}
+		return p.root == nil
+	}, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestPersistentOldVersionUnaffected ensures that Insert and Delete
+// leave the receiver's version of the tree completely unchanged, so
+// concurrent readers holding an older Persistent never observe a
+// mutation made by a newer one.
+func TestPersistentOldVersionUnaffected(t *testing.T) {
+	v0 := NewPersistent([]*T{
+		{Point: Point{0, 1}},
+		{Point: Point{6, 9}},
+		{Point: Point{4, 6}},
+		{Point: Point{2, 3}},
+	})
+
+	before := v0.InRange(Point{0, 0}, 100, nil)
+	if len(before) != 4 {
+		t.Fatalf("got %d points, want 4", len(before))
+	}
+
+	v1 := v0.Insert(Point{5, 5})
+	v2, ok := v1.Delete(Point{0, 1})
+	if !ok {
+		t.Fatal("expected to delete {0, 1}")
+	}
+
+	after := v0.InRange(Point{0, 0}, 100, nil)
+	if len(after) != 4 {
+		t.Fatalf("v0 changed: got %d points, want 4", len(after))
+	}
+	for _, n := range before {
+		found := false
+		for _, m := range after {
+			if m.Point == n.Point {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("v0 lost point %v after mutating v1/v2", n.Point)
+		}
+	}
+
+	if got := len(v1.InRange(Point{0, 0}, 100, nil)); got != 5 {
+		t.Fatalf("v1: got %d points, want 5", got)
+	}
+	if got := len(v2.InRange(Point{0, 0}, 100, nil)); got != 4 {
+		t.Fatalf("v2: got %d points, want 4", got)
+	}
+}